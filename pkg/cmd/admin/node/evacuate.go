@@ -2,49 +2,120 @@ package node
 
 import (
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/golang/glog"
 	"github.com/spf13/cobra"
 
 	kapi "k8s.io/kubernetes/pkg/api"
+	kapierrs "k8s.io/kubernetes/pkg/api/errors"
 	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/apis/policy"
 	"k8s.io/kubernetes/pkg/fields"
 	"k8s.io/kubernetes/pkg/labels"
 	kerrors "k8s.io/kubernetes/pkg/util/errors"
 )
 
 const (
-	flagGracePeriod = "grace-period"
-	flagDryRun      = "dry-run"
-	flagForce       = "force"
+	flagGracePeriod     = "grace-period"
+	flagDryRun          = "dry-run"
+	flagForce           = "force"
+	flagEvictionPolicy  = "eviction-policy"
+	flagPodSelector     = "pod-selector"
+	flagIgnoreDaemonSet = "ignore-daemonsets"
+	flagDeleteLocalData = "delete-local-data"
+	flagTimeout         = "timeout"
+	flagMaxParallel     = "max-parallel"
+
+	// EvictionPolicyDelete deletes pods directly via Pods().Delete, bypassing
+	// PodDisruptionBudgets. Kept for compatibility with clusters that do not
+	// yet serve the eviction subresource.
+	EvictionPolicyDelete = "delete"
+	// EvictionPolicyEvict evicts pods via the Eviction subresource so that
+	// PodDisruptionBudgets are honored by the API server.
+	EvictionPolicyEvict = "evict"
 )
 
+// controllerKinds are the owner reference kinds evacuation considers a pod to
+// be "backed by a controller" for, mirroring modern drain semantics.
+var controllerKinds = map[string]bool{
+	"ReplicationController": true,
+	"ReplicaSet":            true,
+	"StatefulSet":           true,
+	"DaemonSet":             true,
+	"Job":                   true,
+}
+
 type EvacuateOptions struct {
 	Options *NodeOptions
 
 	// Optional params
-	DryRun      bool
-	Force       bool
-	GracePeriod int64
+	DryRun           bool
+	Force            bool
+	GracePeriod      int64
+	EvictionPolicy   string
+	PodSelector      string
+	IgnoreDaemonSets bool
+	DeleteLocalData  bool
+	Timeout          time.Duration
+	MaxParallel      int
 }
 
 // NewEvacuateOptions creates a new EvacuateOptions with default values.
 func NewEvacuateOptions(nodeOptions *NodeOptions) *EvacuateOptions {
 	return &EvacuateOptions{
-		Options:     nodeOptions,
-		DryRun:      false,
-		Force:       false,
-		GracePeriod: 30,
+		Options:        nodeOptions,
+		DryRun:         false,
+		Force:          false,
+		GracePeriod:    30,
+		EvictionPolicy: EvictionPolicyEvict,
+		Timeout:        5 * time.Minute,
+		MaxParallel:    5,
+	}
+}
+
+// evictionPolicyValue adapts EvacuateOptions.EvictionPolicy to pflag.Value
+// so it can be set and validated directly from the command line, rather
+// than silently falling through removePod's dispatch on an unrecognized
+// value.
+type evictionPolicyValue struct {
+	policy *string
+}
+
+func (v *evictionPolicyValue) String() string {
+	if *v.policy == "" {
+		return EvictionPolicyEvict
+	}
+	return *v.policy
+}
+
+func (v *evictionPolicyValue) Set(s string) error {
+	switch s {
+	case EvictionPolicyEvict, EvictionPolicyDelete:
+		*v.policy = s
+		return nil
+	default:
+		return fmt.Errorf("invalid value %q for --%s: must be %q or %q", s, flagEvictionPolicy, EvictionPolicyEvict, EvictionPolicyDelete)
 	}
 }
 
+func (v *evictionPolicyValue) Type() string {
+	return "string"
+}
+
 func (e *EvacuateOptions) AddFlags(cmd *cobra.Command) {
 	flags := cmd.Flags()
 
 	flags.BoolVar(&e.DryRun, flagDryRun, e.DryRun, "Show pods that will be migrated. Optional param for --evacuate")
-	flags.BoolVar(&e.Force, flagForce, e.Force, "Delete pods not backed by replication controller. Optional param for --evacuate")
+	flags.BoolVar(&e.Force, flagForce, e.Force, "Delete pods not backed by a controller. Optional param for --evacuate")
 	flags.Int64Var(&e.GracePeriod, flagGracePeriod, e.GracePeriod, "Grace period (seconds) for pods being deleted. Optional param for --evacuate")
-
+	flags.Var(&evictionPolicyValue{&e.EvictionPolicy}, flagEvictionPolicy, "How to remove pods from the node: 'evict' uses the Eviction subresource so PodDisruptionBudgets are respected, 'delete' deletes pods directly. Optional param for --evacuate")
+	flags.StringVar(&e.PodSelector, flagPodSelector, e.PodSelector, "Label selector to filter pods on the node. If unset, falls back to the node command's --pod-selector. Optional param for --evacuate")
+	flags.BoolVar(&e.IgnoreDaemonSets, flagIgnoreDaemonSet, e.IgnoreDaemonSets, "Ignore pods backed by a DaemonSet instead of failing on them. Optional param for --evacuate")
+	flags.BoolVar(&e.DeleteLocalData, flagDeleteLocalData, e.DeleteLocalData, "Continue even if pods use emptyDir volumes, whose data is lost when the pod is removed. Optional param for --evacuate")
+	flags.DurationVar(&e.Timeout, flagTimeout, e.Timeout, "Length of time to retry evicting pods before giving up. 0 retries forever. Optional param for --evacuate")
+	flags.IntVar(&e.MaxParallel, flagMaxParallel, e.MaxParallel, "Maximum number of pods to evacuate concurrently. Optional param for --evacuate")
 }
 
 func (e *EvacuateOptions) Run() error {
@@ -78,7 +149,11 @@ func (e *EvacuateOptions) RunEvacuate(node *kapi.Node) error {
 		return fmt.Errorf("Node '%s' must be unschedulable to perform evacuation.\nYou can mark the node unschedulable with 'openshift admin manage-node %s --schedulable=false'", node.ObjectMeta.Name, node.ObjectMeta.Name)
 	}
 
-	labelSelector, err := labels.Parse(e.Options.PodSelector)
+	podSelector := e.PodSelector
+	if podSelector == "" {
+		podSelector = e.Options.PodSelector
+	}
+	labelSelector, err := labels.Parse(podSelector)
 	if err != nil {
 		return err
 	}
@@ -99,41 +174,54 @@ func (e *EvacuateOptions) RunEvacuate(node *kapi.Node) error {
 		return err
 	}
 
-	errList := []error{}
-	firstPod := true
 	numPodsWithNoRC := 0
-	deleteOptions := e.makeDeleteOptions()
-
+	daemonSetPods := []string{}
+	emptyDirPods := []string{}
+	toEvacuate := []kapi.Pod{}
 	for _, pod := range pods.Items {
-		foundrc := false
-		for _, rc := range rcs.Items {
-			selector := labels.SelectorFromSet(rc.Spec.Selector)
-			if selector.Matches(labels.Set(pod.Labels)) {
-				foundrc = true
-				break
+		if isDaemonSetPod(pod) {
+			if !e.IgnoreDaemonSets {
+				daemonSetPods = append(daemonSetPods, pod.Name)
 			}
+			continue
+		}
+		if !e.DeleteLocalData && usesEmptyDir(pod) {
+			emptyDirPods = append(emptyDirPods, pod.Name)
+			continue
 		}
 
-		if firstPod {
+		if isBackedByController(pod, rcs.Items) || e.Force {
+			toEvacuate = append(toEvacuate, pod)
+		} else { // Pods without a controller and no --force option
+			numPodsWithNoRC++
+		}
+	}
+
+	// Scan the whole node for disqualifying pods before printing or
+	// evacuating anything: bailing out mid-scan left every pod already
+	// classified as eligible un-evacuated, with the "Migrating" header
+	// already printed implying otherwise.
+	errList := []error{}
+	if len(daemonSetPods) > 0 {
+		errList = append(errList, fmt.Errorf("pods %v are backed by a DaemonSet; pass --ignore-daemonsets to evacuate the node anyway", daemonSetPods))
+	}
+	if len(emptyDirPods) > 0 {
+		errList = append(errList, fmt.Errorf("pods %v use an emptyDir volume whose data would be lost; pass --delete-local-data to evacuate them anyway", emptyDirPods))
+	}
+
+	for i, pod := range toEvacuate {
+		if i == 0 {
 			fmt.Fprint(e.Options.ErrWriter, "\nMigrating these pods on node: ", node.ObjectMeta.Name, "\n\n")
-			firstPod = false
 			printerWithHeaders.PrintObj(&pod, e.Options.Writer)
 		} else {
 			printerNoHeaders.PrintObj(&pod, e.Options.Writer)
 		}
-
-		if foundrc || e.Force {
-			if err := e.Options.Kclient.Pods(pod.Namespace).Delete(pod.Name, deleteOptions); err != nil {
-				glog.Errorf("Unable to delete a pod: %+v, error: %v", pod, err)
-				errList = append(errList, err)
-				continue
-			}
-		} else { // Pods without replication controller and no --force option
-			numPodsWithNoRC++
-		}
 	}
+
+	errList = append(errList, e.evacuatePods(toEvacuate)...)
+
 	if numPodsWithNoRC > 0 {
-		err := fmt.Errorf(`Unable to evacuate some pods because they are not backed by replication controller.
+		err := fmt.Errorf(`Unable to evacuate some pods because they are not backed by a controller.
 Suggested options:
 - You can list bare pods in json/yaml format using '--list-pods -o json|yaml'
 - Force deletion of bare pods with --force option to --evacuate
@@ -148,7 +236,113 @@ Suggested options:
 	return nil
 }
 
+// evacuatePods removes pods using a bounded worker pool sized by
+// e.MaxParallel, retrying individual evictions that are rejected by a
+// PodDisruptionBudget (HTTP 429) until e.Timeout elapses. It returns a
+// per-pod summary of any failures.
+func (e *EvacuateOptions) evacuatePods(pods []kapi.Pod) []error {
+	workers := e.MaxParallel
+	if workers <= 0 {
+		workers = 1
+	}
+
+	sem := make(chan struct{}, workers)
+	errs := make([]error, len(pods))
+	var wg sync.WaitGroup
+
+	for i, pod := range pods {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, pod kapi.Pod) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := e.removePod(pod); err != nil {
+				glog.Errorf("Unable to evacuate pod: %+v, error: %v", pod, err)
+				errs[i] = fmt.Errorf("pod %s/%s: %v", pod.Namespace, pod.Name, err)
+			}
+		}(i, pod)
+	}
+	wg.Wait()
+
+	errList := []error{}
+	for _, err := range errs {
+		if err != nil {
+			errList = append(errList, err)
+		}
+	}
+	return errList
+}
+
+// removePod deletes or evicts a single pod according to e.EvictionPolicy,
+// backing off and retrying while the API server reports the PodDisruptionBudget
+// is not yet satisfied (429), until e.Timeout elapses.
+func (e *EvacuateOptions) removePod(pod kapi.Pod) error {
+	if e.EvictionPolicy == EvictionPolicyDelete {
+		return e.Options.Kclient.Pods(pod.Namespace).Delete(pod.Name, e.makeDeleteOptions())
+	}
+
+	eviction := &policy.Eviction{
+		ObjectMeta:    kapi.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+		DeleteOptions: e.makeDeleteOptions(),
+	}
+
+	deadline := time.Now().Add(e.Timeout)
+	backoff := 1 * time.Second
+	for {
+		err := e.Options.Kclient.Policy().Evictions(pod.Namespace).Evict(eviction)
+		if err == nil {
+			return nil
+		}
+		if !kapierrs.IsTooManyRequests(err) {
+			return err
+		}
+		if e.Timeout > 0 && time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for PodDisruptionBudget to allow eviction: %v", err)
+		}
+		time.Sleep(backoff)
+		if backoff < 10*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
 // makeDeleteOptions creates the delete options that will be used for pod evacuation.
 func (e *EvacuateOptions) makeDeleteOptions() *kapi.DeleteOptions {
 	return &kapi.DeleteOptions{GracePeriodSeconds: &e.GracePeriod}
 }
+
+// isBackedByController reports whether pod is owned by a ReplicationController,
+// ReplicaSet, StatefulSet, DaemonSet or Job, either via OwnerReferences or,
+// for the legacy ReplicationController case, by label selector matching.
+func isBackedByController(pod kapi.Pod, rcs []kapi.ReplicationController) bool {
+	for _, ref := range pod.ObjectMeta.OwnerReferences {
+		if controllerKinds[ref.Kind] {
+			return true
+		}
+	}
+	for _, rc := range rcs {
+		selector := labels.SelectorFromSet(rc.Spec.Selector)
+		if selector.Matches(labels.Set(pod.Labels)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isDaemonSetPod(pod kapi.Pod) bool {
+	for _, ref := range pod.ObjectMeta.OwnerReferences {
+		if ref.Kind == "DaemonSet" {
+			return true
+		}
+	}
+	return false
+}
+
+func usesEmptyDir(pod kapi.Pod) bool {
+	for _, vol := range pod.Spec.Volumes {
+		if vol.EmptyDir != nil {
+			return true
+		}
+	}
+	return false
+}
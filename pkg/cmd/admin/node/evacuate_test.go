@@ -0,0 +1,95 @@
+package node
+
+import (
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+)
+
+func TestIsDaemonSetPod(t *testing.T) {
+	daemonSetPod := kapi.Pod{
+		ObjectMeta: kapi.ObjectMeta{
+			OwnerReferences: []unversioned.OwnerReference{{Kind: "DaemonSet", Name: "ds"}},
+		},
+	}
+	if !isDaemonSetPod(daemonSetPod) {
+		t.Error("expected pod owned by a DaemonSet to be detected")
+	}
+
+	rcPod := kapi.Pod{
+		ObjectMeta: kapi.ObjectMeta{
+			OwnerReferences: []unversioned.OwnerReference{{Kind: "ReplicationController", Name: "rc"}},
+		},
+	}
+	if isDaemonSetPod(rcPod) {
+		t.Error("expected pod owned by a ReplicationController not to be detected as a DaemonSet pod")
+	}
+}
+
+func TestIsBackedByController(t *testing.T) {
+	ownerRefPod := kapi.Pod{
+		ObjectMeta: kapi.ObjectMeta{
+			OwnerReferences: []unversioned.OwnerReference{{Kind: "ReplicaSet", Name: "rs"}},
+		},
+	}
+	if !isBackedByController(ownerRefPod, nil) {
+		t.Error("expected pod with a ReplicaSet owner reference to be backed by a controller")
+	}
+
+	rc := kapi.ReplicationController{
+		Spec: kapi.ReplicationControllerSpec{Selector: map[string]string{"app": "web"}},
+	}
+	selectorMatchPod := kapi.Pod{
+		ObjectMeta: kapi.ObjectMeta{Labels: map[string]string{"app": "web"}},
+	}
+	if !isBackedByController(selectorMatchPod, []kapi.ReplicationController{rc}) {
+		t.Error("expected pod matching a ReplicationController's selector to be backed by a controller")
+	}
+
+	barePod := kapi.Pod{ObjectMeta: kapi.ObjectMeta{Labels: map[string]string{"app": "other"}}}
+	if isBackedByController(barePod, []kapi.ReplicationController{rc}) {
+		t.Error("expected bare pod with no matching owner to not be backed by a controller")
+	}
+}
+
+func TestUsesEmptyDir(t *testing.T) {
+	pod := kapi.Pod{
+		Spec: kapi.PodSpec{
+			Volumes: []kapi.Volume{
+				{Name: "data", VolumeSource: kapi.VolumeSource{EmptyDir: &kapi.EmptyDirVolumeSource{}}},
+			},
+		},
+	}
+	if !usesEmptyDir(pod) {
+		t.Error("expected pod with an emptyDir volume to be detected")
+	}
+
+	noVolumePod := kapi.Pod{}
+	if usesEmptyDir(noVolumePod) {
+		t.Error("expected pod with no volumes to not use emptyDir")
+	}
+}
+
+func TestEvictionPolicyValueSet(t *testing.T) {
+	var policy string
+	v := &evictionPolicyValue{&policy}
+
+	if err := v.Set(EvictionPolicyDelete); err != nil {
+		t.Fatalf("unexpected error setting %q: %v", EvictionPolicyDelete, err)
+	}
+	if policy != EvictionPolicyDelete {
+		t.Errorf("policy = %q, want %q", policy, EvictionPolicyDelete)
+	}
+
+	if err := v.Set(EvictionPolicyEvict); err != nil {
+		t.Fatalf("unexpected error setting %q: %v", EvictionPolicyEvict, err)
+	}
+	if policy != EvictionPolicyEvict {
+		t.Errorf("policy = %q, want %q", policy, EvictionPolicyEvict)
+	}
+
+	if err := v.Set("bogus"); err == nil {
+		t.Error("expected an error for an unrecognized eviction policy")
+	}
+}
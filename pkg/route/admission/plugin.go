@@ -0,0 +1,61 @@
+// Package admission validates and defaults Route objects on create and
+// update, both as an in-process admission plugin and, via Server, as a
+// standalone HTTPS endpoint other admission chains can call out to.
+package admission
+
+import (
+	"io"
+
+	kadmission "k8s.io/kubernetes/pkg/admission"
+	kapierrors "k8s.io/kubernetes/pkg/api/errors"
+
+	routeapi "github.com/openshift/origin/pkg/route/api/v1beta3"
+	"github.com/openshift/origin/pkg/route/validation"
+)
+
+const pluginName = "RouteValidation"
+
+func init() {
+	kadmission.RegisterPlugin(pluginName, func(config io.Reader) (kadmission.Interface, error) {
+		return NewRouteValidator(), nil
+	})
+}
+
+// RouteValidator is a kadmission.Interface that defaults and validates
+// Route objects as they are created or updated.
+type RouteValidator struct{}
+
+// NewRouteValidator creates an admission plugin that defaults and validates
+// Routes.
+func NewRouteValidator() *RouteValidator {
+	return &RouteValidator{}
+}
+
+// Admit defaults and validates route resources on Create and Update,
+// rejecting the request with the accumulated field errors if validation
+// fails.
+func (v *RouteValidator) Admit(a kadmission.Attributes) error {
+	if a.GetResource().GroupResource().Resource != "routes" {
+		return nil
+	}
+	if a.GetOperation() != kadmission.Create && a.GetOperation() != kadmission.Update {
+		return nil
+	}
+
+	route, ok := a.GetObject().(*routeapi.Route)
+	if !ok {
+		return nil
+	}
+
+	validation.SetDefaults_Route(route)
+
+	if errs := validation.ValidateRoute(route); len(errs) > 0 {
+		return kapierrors.NewInvalid(a.GetKind().GroupKind(), route.Name, errs)
+	}
+	return nil
+}
+
+// Handles reports that this plugin only needs to run on Create and Update.
+func (v *RouteValidator) Handles(operation kadmission.Operation) bool {
+	return operation == kadmission.Create || operation == kadmission.Update
+}
@@ -0,0 +1,63 @@
+package admission
+
+import (
+	"encoding/json"
+	"net/http"
+
+	routeapi "github.com/openshift/origin/pkg/route/api/v1beta3"
+	"github.com/openshift/origin/pkg/route/validation"
+)
+
+// AdmissionRequest is the body a caller POSTs to Server to have a Route
+// defaulted and validated out-of-process.
+type AdmissionRequest struct {
+	Route routeapi.Route `json:"route"`
+}
+
+// AdmissionResponse carries the defaulted route back alongside any
+// validation errors found. Allowed is true iff Errors is empty.
+type AdmissionResponse struct {
+	Allowed bool           `json:"allowed"`
+	Route   routeapi.Route `json:"route"`
+	Errors  []string       `json:"errors,omitempty"`
+}
+
+// Server exposes route defaulting and validation over HTTPS, so that
+// clients which cannot load the in-process RouteValidator plugin (e.g. a
+// separate `oc` invocation, or a router written in another language) can
+// still apply the same rules.
+type Server struct{}
+
+// NewServer creates a Server ready to be used as an http.Handler.
+func NewServer() *Server {
+	return &Server{}
+}
+
+// ServeHTTP implements http.Handler, defaulting and validating the Route in
+// the request body and echoing back the result as an AdmissionResponse.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req AdmissionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	validation.SetDefaults_Route(&req.Route)
+	errs := validation.ValidateRoute(&req.Route)
+
+	resp := AdmissionResponse{
+		Allowed: len(errs) == 0,
+		Route:   req.Route,
+	}
+	for _, err := range errs {
+		resp.Errors = append(resp.Errors, err.Error())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -99,11 +99,13 @@ type RouteIngressConditionType string
 const (
 	// RouteAdmitted means the route is able to service requests for the provided Host
 	RouteAdmitted RouteIngressConditionType = "Admitted"
-	// TODO: add other route condition types
+	// RouteRejected means the route was not accepted by a router, for example
+	// because it does not match the router shard's namespace or label
+	// selector, and will not be serviced by that router for the provided Host
+	RouteRejected RouteIngressConditionType = "Rejected"
 )
 
 // RouteIngressCondition contains details for the current condition of this pod.
-// TODO: add LastTransitionTime, Reason, Message to match NodeCondition api.
 type RouteIngressCondition struct {
 	// Type is the type of the condition.
 	// Currently only Ready.
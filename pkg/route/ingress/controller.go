@@ -0,0 +1,370 @@
+// Package ingress contains a controller that watches Kubernetes Ingress
+// resources and synthesizes equivalent OpenShift Route objects, giving users
+// a drop-in path from generic Ingress manifests to OpenShift routing without
+// hand-writing Route YAML.
+package ingress
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang/glog"
+
+	kapi "k8s.io/kubernetes/pkg/api/v1beta3"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+	"k8s.io/kubernetes/pkg/client/cache"
+	"k8s.io/kubernetes/pkg/controller/framework"
+	"k8s.io/kubernetes/pkg/labels"
+	"k8s.io/kubernetes/pkg/runtime"
+	kerrors "k8s.io/kubernetes/pkg/util/errors"
+	"k8s.io/kubernetes/pkg/util/intstr"
+	"k8s.io/kubernetes/pkg/watch"
+
+	"github.com/openshift/origin/pkg/client"
+	routeapi "github.com/openshift/origin/pkg/route/api/v1beta3"
+)
+
+// Annotations recognized on an Ingress that customize the Route(s) the
+// controller creates for it. Any annotation not set falls back to the
+// Route API's normal defaulting behavior.
+const (
+	annotationPrefix = "route.openshift.io/"
+
+	// TerminationAnnotation selects the TLSConfig.Termination of the
+	// generated Route, e.g. "edge", "passthrough" or "reencrypt".
+	TerminationAnnotation = annotationPrefix + "termination"
+	// InsecureEdgeTerminationPolicyAnnotation selects TLSConfig.InsecureEdgeTerminationPolicy.
+	InsecureEdgeTerminationPolicyAnnotation = annotationPrefix + "insecure-edge-termination-policy"
+	// TargetPortAnnotation overrides the named target port used on the
+	// generated Route's RoutePort. Without it, the Ingress backend's
+	// servicePort is used when set, and RoutePort is otherwise left unset.
+	TargetPortAnnotation = annotationPrefix + "target-port"
+	// WeightAnnotationPrefix, suffixed with a backend service name, sets the
+	// Weight of that backend when an Ingress rule fans out to more than one
+	// service via AlternateBackends.
+	WeightAnnotationPrefix = annotationPrefix + "weight."
+
+	// ownerAnnotation records the Ingress that a Route was generated from.
+	// Stale Routes are found for pruning on update and delete by ownerLabel,
+	// not by this annotation.
+	ownerAnnotation = annotationPrefix + "generated-from"
+)
+
+// ExtensionsIngressGetter is the subset of the Kubernetes extensions client
+// this controller needs to list and watch Ingress objects.
+type ExtensionsIngressGetter interface {
+	Ingress(namespace string) IngressInterface
+}
+
+// IngressInterface lists and watches Ingress objects in a namespace.
+type IngressInterface interface {
+	List(options kapi.ListOptions) (*extensions.IngressList, error)
+	Watch(options kapi.ListOptions) (watch.Interface, error)
+}
+
+// IngressController watches Kubernetes Ingress objects and keeps a matching
+// set of Routes in sync, one per host+path rule.
+type IngressController struct {
+	client client.Interface
+
+	ingressStore  cache.Store
+	ingressRunner *framework.Controller
+
+	queue *ingressQueue
+}
+
+// NewIngressController creates a new IngressController that uses osClient to
+// read and write Routes and kClient to watch Ingress objects.
+func NewIngressController(osClient client.Interface, kClient ExtensionsIngressGetter, resyncPeriod int64) *IngressController {
+	c := &IngressController{
+		client: osClient,
+		queue:  newIngressQueue(),
+	}
+
+	c.ingressStore, c.ingressRunner = framework.NewInformer(
+		&cache.ListWatch{
+			ListFunc: func(options kapi.ListOptions) (runtime.Object, error) {
+				return kClient.Ingress(kapi.NamespaceAll).List(options)
+			},
+			WatchFunc: func(options kapi.ListOptions) (watch.Interface, error) {
+				return kClient.Ingress(kapi.NamespaceAll).Watch(options)
+			},
+		},
+		&extensions.Ingress{},
+		time.Duration(resyncDuration(resyncPeriod))*time.Second,
+		framework.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { c.enqueue(obj) },
+			UpdateFunc: func(_, obj interface{}) { c.enqueue(obj) },
+			DeleteFunc: func(obj interface{}) { c.enqueue(obj) },
+		},
+	)
+
+	return c
+}
+
+// Run starts the controller's event loop until stopCh is closed.
+func (c *IngressController) Run(stopCh <-chan struct{}) {
+	glog.V(4).Info("Starting ingress-to-route controller")
+	go c.ingressRunner.Run(stopCh)
+
+	for {
+		key, quit := c.queue.pop()
+		if quit {
+			return
+		}
+		if err := c.sync(key); err != nil {
+			glog.Errorf("Error syncing ingress %s: %v", key, err)
+			c.queue.requeue(key)
+		}
+	}
+}
+
+func (c *IngressController) enqueue(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		glog.Errorf("Couldn't get key for object %+v: %v", obj, err)
+		return
+	}
+	c.queue.add(key)
+}
+
+// sync reconciles the Ingress identified by key with the Routes it should
+// own: generating missing Routes, updating changed ones and removing Routes
+// for rules that no longer exist.
+func (c *IngressController) sync(key string) error {
+	obj, exists, err := c.ingressStore.GetByKey(key)
+	if err != nil {
+		return err
+	}
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return c.deleteRoutesForIngress(namespace, name)
+	}
+
+	ingress := obj.(*extensions.Ingress)
+	desired, err := routesForIngress(ingress)
+	if err != nil {
+		return err
+	}
+
+	existing, err := c.client.Routes(namespace).List(kapi.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{ownerLabel(name): "true"}),
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.reconcile(namespace, desired, existing.Items)
+}
+
+// reconcile creates or updates desired Routes and deletes any existing Route
+// that is no longer described by the Ingress.
+func (c *IngressController) reconcile(namespace string, desired []routeapi.Route, existing []routeapi.Route) error {
+	seen := map[string]bool{}
+	errs := []error{}
+
+	for i := range desired {
+		route := &desired[i]
+		seen[route.Name] = true
+
+		current, err := c.client.Routes(namespace).Get(route.Name)
+		switch {
+		case err == nil:
+			route.ObjectMeta.ResourceVersion = current.ObjectMeta.ResourceVersion
+			if _, err := c.client.Routes(namespace).Update(route); err != nil {
+				errs = append(errs, err)
+			}
+		default:
+			if _, err := c.client.Routes(namespace).Create(route); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	for _, route := range existing {
+		if !seen[route.Name] {
+			if err := c.client.Routes(namespace).Delete(route.Name); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return kerrors.NewAggregate(errs)
+}
+
+func (c *IngressController) deleteRoutesForIngress(namespace, name string) error {
+	routes, err := c.client.Routes(namespace).List(kapi.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{ownerLabel(name): "true"}),
+	})
+	if err != nil {
+		return err
+	}
+	errs := []error{}
+	for _, route := range routes.Items {
+		if err := c.client.Routes(namespace).Delete(route.Name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return kerrors.NewAggregate(errs)
+}
+
+// routesForIngress builds one Route per host+path rule declared on ingress.
+func routesForIngress(ingress *extensions.Ingress) ([]routeapi.Route, error) {
+	primaries := primaryBackends(ingress)
+
+	routes := []routeapi.Route{}
+	for ruleIdx, rule := range ingress.Spec.Rules {
+		for pathIdx, p := range rule.HTTP.Paths {
+			route, err := newRoute(ingress, rule.Host, p, primaries, ruleIdx, pathIdx)
+			if err != nil {
+				return nil, err
+			}
+			routes = append(routes, *route)
+		}
+	}
+	return routes, nil
+}
+
+// primaryBackends collects the backend service names that are themselves
+// some path's primary backend, under any rule of ingress. It lets
+// AlternateBackends tell apart a weight-annotated canary service (no path of
+// its own) from a weight-annotated service that is really a *different*
+// path's own primary, which must not bleed into an unrelated path's Route.
+func primaryBackends(ingress *extensions.Ingress) map[string]bool {
+	primaries := map[string]bool{}
+	for _, rule := range ingress.Spec.Rules {
+		for _, p := range rule.HTTP.Paths {
+			primaries[p.Backend.ServiceName] = true
+		}
+	}
+	return primaries
+}
+
+func newRoute(ingress *extensions.Ingress, host string, p extensions.HTTPIngressPath, primaries map[string]bool, ruleIdx, pathIdx int) (*routeapi.Route, error) {
+	name := fmt.Sprintf("%s-%d-%d", ingress.Name, ruleIdx, pathIdx)
+
+	route := &routeapi.Route{
+		ObjectMeta: kapi.ObjectMeta{
+			Name:      name,
+			Namespace: ingress.Namespace,
+			Labels: map[string]string{
+				ownerLabel(ingress.Name): "true",
+			},
+			Annotations: map[string]string{
+				ownerAnnotation: ingress.Name,
+			},
+		},
+		Spec: routeapi.RouteSpec{
+			Host: host,
+			Path: p.Path,
+			To: routeapi.RouteTargetReference{
+				Kind:   "Service",
+				Name:   p.Backend.ServiceName,
+				Weight: weightFor(ingress, p.Backend.ServiceName),
+			},
+		},
+	}
+
+	if targetPort, ok := ingress.Annotations[TargetPortAnnotation]; ok {
+		route.Spec.Port = &routeapi.RoutePort{TargetPort: intstr.FromString(targetPort)}
+	} else if p.Backend.ServicePort.StrVal != "" || p.Backend.ServicePort.IntVal != 0 {
+		route.Spec.Port = &routeapi.RoutePort{TargetPort: intstr.IntOrString(p.Backend.ServicePort)}
+	}
+
+	if tls := tlsConfigFor(ingress, host); tls != nil {
+		route.Spec.TLS = tls
+	}
+
+	for _, backend := range alternateBackends(ingress, p.Backend.ServiceName, primaries) {
+		route.Spec.AlternateBackends = append(route.Spec.AlternateBackends, routeapi.RouteTargetReference{
+			Kind:   "Service",
+			Name:   backend,
+			Weight: weightFor(ingress, backend),
+		})
+	}
+
+	return route, nil
+}
+
+// tlsConfigFor translates the Ingress TLS block covering host, if any, into
+// a Route TLSConfig using the edge termination type unless overridden by the
+// termination annotation.
+func tlsConfigFor(ingress *extensions.Ingress, host string) *routeapi.TLSConfig {
+	var secretName string
+	for _, t := range ingress.Spec.TLS {
+		for _, h := range t.Hosts {
+			if h == host {
+				secretName = t.SecretName
+			}
+		}
+	}
+	if secretName == "" && ingress.Annotations[TerminationAnnotation] == "" {
+		return nil
+	}
+
+	termination := routeapi.TLSTerminationEdge
+	if v, ok := ingress.Annotations[TerminationAnnotation]; ok {
+		termination = routeapi.TLSTerminationType(v)
+	}
+
+	tls := &routeapi.TLSConfig{Termination: termination}
+	if v, ok := ingress.Annotations[InsecureEdgeTerminationPolicyAnnotation]; ok {
+		tls.InsecureEdgeTerminationPolicy = routeapi.InsecureEdgeTerminationPolicyType(v)
+	}
+	return tls
+}
+
+// alternateBackends returns the names of backend services referenced by
+// weight annotations other than primary, excluding any name in primaries:
+// a service that is itself some path's own primary backend in the Ingress.
+// That exclusion is what lets a canary service weighted via an annotation
+// but with no Ingress path of its own (the common case this annotation
+// exists for) get attached to every path's Route, since weight annotations
+// are not scoped to a host, while a weight annotation that really names
+// another path's own service does not bleed into this path's Route.
+func alternateBackends(ingress *extensions.Ingress, primary string, primaries map[string]bool) []string {
+	names := []string{}
+	for key := range ingress.Annotations {
+		if !hasPrefix(key, WeightAnnotationPrefix) {
+			continue
+		}
+		name := key[len(WeightAnnotationPrefix):]
+		if name == primary || primaries[name] {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func weightFor(ingress *extensions.Ingress, service string) *int32 {
+	v, ok := ingress.Annotations[WeightAnnotationPrefix+service]
+	if !ok {
+		return nil
+	}
+	weight, err := strconv.ParseInt(v, 10, 32)
+	if err != nil {
+		return nil
+	}
+	w := int32(weight)
+	return &w
+}
+
+func hasPrefix(s, prefix string) bool {
+	return len(s) >= len(prefix) && s[:len(prefix)] == prefix
+}
+
+func ownerLabel(ingressName string) string {
+	return annotationPrefix + "ingress." + ingressName
+}
+
+func resyncDuration(seconds int64) int64 {
+	if seconds <= 0 {
+		return 600
+	}
+	return seconds
+}
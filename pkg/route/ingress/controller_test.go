@@ -0,0 +1,123 @@
+package ingress
+
+import (
+	"testing"
+	"time"
+
+	kapi "k8s.io/kubernetes/pkg/api/v1beta3"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+	"k8s.io/kubernetes/pkg/util/intstr"
+)
+
+// TestAlternateBackendsScopedToRule verifies that a weight annotation naming
+// a service used only by an unrelated rule is not pulled into a Route built
+// for a different rule's primary service.
+func TestAlternateBackendsScopedToRule(t *testing.T) {
+	ingress := &extensions.Ingress{
+		ObjectMeta: kapi.ObjectMeta{
+			Name:      "multi",
+			Namespace: "ns",
+			Annotations: map[string]string{
+				WeightAnnotationPrefix + "serviceB": "50",
+			},
+		},
+		Spec: extensions.IngressSpec{
+			Rules: []extensions.IngressRule{
+				{
+					Host: "rule1.example.com",
+					IngressRuleValue: extensions.IngressRuleValue{
+						HTTP: &extensions.HTTPIngressRuleValue{
+							Paths: []extensions.HTTPIngressPath{
+								{Backend: extensions.IngressBackend{ServiceName: "serviceA", ServicePort: intstr.FromInt(80)}},
+							},
+						},
+					},
+				},
+				{
+					Host: "rule2.example.com",
+					IngressRuleValue: extensions.IngressRuleValue{
+						HTTP: &extensions.HTTPIngressRuleValue{
+							Paths: []extensions.HTTPIngressPath{
+								{Backend: extensions.IngressBackend{ServiceName: "serviceB", ServicePort: intstr.FromInt(80)}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	routes, err := routesForIngress(ingress)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i := range routes {
+		r := &routes[i]
+		if r.Spec.To.Name != "serviceA" {
+			continue
+		}
+		if len(r.Spec.AlternateBackends) != 0 {
+			t.Fatalf("expected rule1's Route for serviceA not to alternate to rule2's serviceB, got %+v", r.Spec.AlternateBackends)
+		}
+	}
+}
+
+// TestAlternateBackendsNoOwnPathCanary verifies the common case this
+// annotation exists for: a canary service with no Ingress path of its own,
+// weighted via an annotation alone, gets attached as an AlternateBackends
+// entry on the one path sharing its host.
+func TestAlternateBackendsNoOwnPathCanary(t *testing.T) {
+	ingress := &extensions.Ingress{
+		ObjectMeta: kapi.ObjectMeta{
+			Name:      "canary",
+			Namespace: "ns",
+			Annotations: map[string]string{
+				WeightAnnotationPrefix + "serviceB-canary": "10",
+			},
+		},
+		Spec: extensions.IngressSpec{
+			Rules: []extensions.IngressRule{
+				{
+					Host: "example.com",
+					IngressRuleValue: extensions.IngressRuleValue{
+						HTTP: &extensions.HTTPIngressRuleValue{
+							Paths: []extensions.HTTPIngressPath{
+								{Path: "/", Backend: extensions.IngressBackend{ServiceName: "serviceB", ServicePort: intstr.FromInt(80)}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	routes, err := routesForIngress(ingress)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(routes) != 1 {
+		t.Fatalf("expected exactly one Route, got %d", len(routes))
+	}
+
+	alternates := routes[0].Spec.AlternateBackends
+	if len(alternates) != 1 || alternates[0].Name != "serviceB-canary" {
+		t.Fatalf("expected serviceB's Route to alternate to the path-less canary serviceB-canary, got %+v", alternates)
+	}
+	if alternates[0].Weight == nil || *alternates[0].Weight != 10 {
+		t.Fatalf("expected canary Weight 10, got %+v", alternates[0].Weight)
+	}
+}
+
+// TestResyncDurationAsSecondsDuration verifies resyncDuration's return value
+// converts to a time.Duration of whole seconds, not nanoseconds.
+func TestResyncDurationAsSecondsDuration(t *testing.T) {
+	got := time.Duration(resyncDuration(30)) * time.Second
+	if want := 30 * time.Second; got != want {
+		t.Errorf("resyncDuration(30) as time.Duration = %v, want %v", got, want)
+	}
+
+	if got := time.Duration(resyncDuration(0)) * time.Second; got <= 0 {
+		t.Errorf("resyncDuration(0) produced non-positive duration %v", got)
+	}
+}
@@ -0,0 +1,34 @@
+package ingress
+
+import "k8s.io/kubernetes/pkg/util/workqueue"
+
+// ingressQueue is a thin wrapper around workqueue.Interface that speaks in
+// terms of the namespace/name keys the controller enqueues.
+type ingressQueue struct {
+	workqueue.Interface
+}
+
+func newIngressQueue() *ingressQueue {
+	return &ingressQueue{workqueue.New()}
+}
+
+func (q *ingressQueue) add(key string) {
+	q.Add(key)
+}
+
+// pop blocks until an item is available and returns it, or returns quit=true
+// once the queue has been shut down.
+func (q *ingressQueue) pop() (key string, quit bool) {
+	item, shutdown := q.Get()
+	if shutdown {
+		return "", true
+	}
+	key = item.(string)
+	q.Done(item)
+	return key, false
+}
+
+// requeue re-adds key after a sync failure so it is retried.
+func (q *ingressQueue) requeue(key string) {
+	q.Add(key)
+}
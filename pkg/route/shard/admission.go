@@ -0,0 +1,99 @@
+// Package shard implements admission of Routes onto a particular
+// RouterShard, so that multi-router deployments can tell which shard
+// accepted a given route and why.
+package shard
+
+import (
+	"fmt"
+
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	kapi "k8s.io/kubernetes/pkg/api/v1beta3"
+	"k8s.io/kubernetes/pkg/labels"
+
+	routeapi "github.com/openshift/origin/pkg/route/api/v1beta3"
+)
+
+// ShardConfig pairs a RouterShard with the namespace/label selector that
+// determines which routes it is allowed to admit.
+type ShardConfig struct {
+	routeapi.RouterShard
+
+	// NamespaceSelector, if non-empty, restricts admission to routes in a
+	// matching namespace.
+	NamespaceSelector labels.Selector
+	// LabelSelector, if non-nil, restricts admission to routes carrying
+	// matching labels.
+	LabelSelector labels.Selector
+}
+
+// ShardAdmitter decides whether a shard should admit a Route, defaulting an
+// empty host and recording the outcome as a RouteIngress entry for the
+// shard's router.
+type ShardAdmitter struct {
+	shard ShardConfig
+}
+
+// NewShardAdmitter creates a ShardAdmitter for the given shard configuration.
+func NewShardAdmitter(shard ShardConfig) *ShardAdmitter {
+	return &ShardAdmitter{shard: shard}
+}
+
+// Admit evaluates route against the shard's selectors, defaults an empty
+// route.Spec.Host only when the route is admitted (a rejected route's host
+// is left untouched), and writes back a RouteIngress entry recording
+// whether the shard admitted or rejected the route and why. It returns
+// whether the route was admitted.
+func (a *ShardAdmitter) Admit(route *routeapi.Route, namespaceLabels labels.Labels) bool {
+	admitted, reason, message := a.evaluate(route, namespaceLabels)
+	if admitted && route.Spec.Host == "" {
+		route.Spec.Host = fmt.Sprintf("%s-%s.%s", route.Name, route.Namespace, a.shard.DNSSuffix)
+	}
+
+	a.recordIngress(route, admitted, reason, message)
+	return admitted
+}
+
+func (a *ShardAdmitter) evaluate(route *routeapi.Route, namespaceLabels labels.Labels) (bool, string, string) {
+	if a.shard.NamespaceSelector != nil && !a.shard.NamespaceSelector.Matches(namespaceLabels) {
+		return false, "NamespaceSelectorMismatch", fmt.Sprintf("namespace %s does not match shard %s's namespace selector", route.Namespace, a.shard.ShardName)
+	}
+	if a.shard.LabelSelector != nil && !a.shard.LabelSelector.Matches(labels.Set(route.Labels)) {
+		return false, "LabelSelectorMismatch", fmt.Sprintf("route %s/%s does not match shard %s's label selector", route.Namespace, route.Name, a.shard.ShardName)
+	}
+	return true, "Admitted", fmt.Sprintf("route admitted by shard %s", a.shard.ShardName)
+}
+
+// recordIngress updates, or adds, the RouteIngress entry for this shard's
+// router so that Reason, Message and LastTransitionTime are always
+// populated, regardless of whether the route was admitted or rejected.
+func (a *ShardAdmitter) recordIngress(route *routeapi.Route, admitted bool, reason, message string) {
+	conditionType := routeapi.RouteAdmitted
+	status := kapi.ConditionTrue
+	if !admitted {
+		conditionType = routeapi.RouteRejected
+		status = kapi.ConditionFalse
+	}
+
+	now := unversioned.Now()
+	condition := routeapi.RouteIngressCondition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: &now,
+	}
+
+	for i, ingress := range route.Status.Ingress {
+		if ingress.RouterName == a.shard.ShardName {
+			route.Status.Ingress[i].Host = route.Spec.Host
+			route.Status.Ingress[i].Conditions = []routeapi.RouteIngressCondition{condition}
+			return
+		}
+	}
+
+	route.Status.Ingress = append(route.Status.Ingress, routeapi.RouteIngress{
+		Host:       route.Spec.Host,
+		RouterName: a.shard.ShardName,
+		Conditions: []routeapi.RouteIngressCondition{condition},
+	})
+}
@@ -0,0 +1,52 @@
+package shard
+
+import (
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api/v1beta3"
+	"k8s.io/kubernetes/pkg/labels"
+
+	routeapi "github.com/openshift/origin/pkg/route/api/v1beta3"
+)
+
+// TestAdmitRejectedDoesNotDefaultHost verifies that a shard which rejects a
+// route leaves Spec.Host untouched, so a later shard that would admit the
+// route still sees an empty host and applies its own DNS suffix.
+func TestAdmitRejectedDoesNotDefaultHost(t *testing.T) {
+	shard := ShardConfig{
+		RouterShard:       routeapi.RouterShard{ShardName: "west", DNSSuffix: "west.example.com"},
+		NamespaceSelector: labels.Set{"region": "west"}.AsSelector(),
+	}
+	admitter := NewShardAdmitter(shard)
+
+	route := &routeapi.Route{
+		ObjectMeta: kapi.ObjectMeta{Name: "r", Namespace: "ns"},
+	}
+
+	if admitted := admitter.Admit(route, labels.Set{"region": "east"}); admitted {
+		t.Fatal("expected shard to reject route from a non-matching namespace")
+	}
+	if route.Spec.Host != "" {
+		t.Errorf("rejecting shard defaulted Spec.Host to %q, want empty so a later shard can default it", route.Spec.Host)
+	}
+}
+
+// TestAdmitAcceptedDefaultsHost verifies that an admitting shard still
+// defaults an empty host.
+func TestAdmitAcceptedDefaultsHost(t *testing.T) {
+	shard := ShardConfig{
+		RouterShard: routeapi.RouterShard{ShardName: "east", DNSSuffix: "east.example.com"},
+	}
+	admitter := NewShardAdmitter(shard)
+
+	route := &routeapi.Route{
+		ObjectMeta: kapi.ObjectMeta{Name: "r", Namespace: "ns"},
+	}
+
+	if admitted := admitter.Admit(route, labels.Set{}); !admitted {
+		t.Fatal("expected shard with no selectors to admit route")
+	}
+	if want := "r-ns.east.example.com"; route.Spec.Host != want {
+		t.Errorf("Spec.Host = %q, want %q", route.Spec.Host, want)
+	}
+}
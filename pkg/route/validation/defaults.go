@@ -0,0 +1,25 @@
+package validation
+
+import routeapi "github.com/openshift/origin/pkg/route/api/v1beta3"
+
+const defaultTargetWeight = int32(100)
+
+// SetDefaults_Route defaults a Route's target references: an unset Weight
+// becomes 100 and an unset Kind becomes "Service", matching the only kind
+// routes currently support.
+func SetDefaults_Route(route *routeapi.Route) {
+	defaultTargetReference(&route.Spec.To)
+	for i := range route.Spec.AlternateBackends {
+		defaultTargetReference(&route.Spec.AlternateBackends[i])
+	}
+}
+
+func defaultTargetReference(ref *routeapi.RouteTargetReference) {
+	if ref.Kind == "" {
+		ref.Kind = "Service"
+	}
+	if ref.Weight == nil {
+		w := defaultTargetWeight
+		ref.Weight = &w
+	}
+}
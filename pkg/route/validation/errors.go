@@ -0,0 +1,8 @@
+package validation
+
+import "errors"
+
+var (
+	errInvalidPEM           = errors.New("certificate does not decode as PEM")
+	errInvalidCACertificate = errors.New("caCertificate does not decode as PEM")
+)
@@ -0,0 +1,147 @@
+// Package validation validates Route objects and applies their field
+// defaults, so that the same rules can be shared between the in-process
+// admission plugin and a standalone admission webhook server.
+package validation
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+
+	"k8s.io/kubernetes/pkg/util/validation/field"
+
+	routeapi "github.com/openshift/origin/pkg/route/api/v1beta3"
+)
+
+// ValidateRoute validates that a Route's spec is internally consistent:
+// termination and TLS options must agree, and any supplied certificate chain
+// must actually cover the route's host.
+func ValidateRoute(route *routeapi.Route) field.ErrorList {
+	allErrs := field.ErrorList{}
+	specPath := field.NewPath("spec")
+
+	if route.Spec.Path != "" && route.Spec.TLS != nil && route.Spec.TLS.Termination == routeapi.TLSTerminationPassthrough {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("path"), route.Spec.Path, "passthrough routes cannot use a path since HAProxy cannot route by path without terminating TLS"))
+	}
+
+	if route.Spec.TLS != nil {
+		allErrs = append(allErrs, validateTLS(route, specPath.Child("tls"))...)
+	}
+
+	allErrs = append(allErrs, validateTargetKind(route.Spec.To, specPath.Child("to", "kind"))...)
+	allErrs = append(allErrs, validateTargetWeight(route.Spec.To, specPath.Child("to", "weight"))...)
+	for i, backend := range route.Spec.AlternateBackends {
+		allErrs = append(allErrs, validateTargetKind(backend, specPath.Child("alternateBackends").Index(i).Child("kind"))...)
+		allErrs = append(allErrs, validateTargetWeight(backend, specPath.Child("alternateBackends").Index(i).Child("weight"))...)
+	}
+
+	return allErrs
+}
+
+// validateTargetKind enforces that a RouteTargetReference names the only
+// kind Routes currently support; a nil/empty Kind is left to defaulting and
+// is not an error here.
+func validateTargetKind(ref routeapi.RouteTargetReference, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if ref.Kind != "" && ref.Kind != "Service" {
+		allErrs = append(allErrs, field.NotSupported(fldPath, ref.Kind, []string{"Service"}))
+	}
+	return allErrs
+}
+
+// validateTargetWeight enforces the 1-256 range HAProxy server weights must
+// fall within; a nil Weight is left to defaulting and is not an error here.
+func validateTargetWeight(ref routeapi.RouteTargetReference, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if ref.Weight != nil && (*ref.Weight < 1 || *ref.Weight > 256) {
+		allErrs = append(allErrs, field.Invalid(fldPath, *ref.Weight, "must be between 1 and 256"))
+	}
+	return allErrs
+}
+
+func validateTLS(route *routeapi.Route, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	tls := route.Spec.TLS
+
+	switch tls.Termination {
+	case routeapi.TLSTerminationPassthrough:
+		if tls.Certificate != "" || tls.Key != "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("certificate"), "<redacted>", "passthrough routes may not carry a certificate or key; the destination terminates TLS"))
+		}
+	case routeapi.TLSTerminationEdge, routeapi.TLSTerminationReencrypt:
+		if (tls.Certificate == "") != (tls.Key == "") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("key"), "<redacted>", "certificate and key must both be set, or both left empty"))
+		} else if tls.Certificate != "" {
+			if err := verifyCertificate(tls.Certificate, tls.CACertificate, route.Spec.Host); err != nil {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("certificate"), "<redacted>", err.Error()))
+			}
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("termination"), tls.Termination, []string{
+			string(routeapi.TLSTerminationEdge), string(routeapi.TLSTerminationPassthrough), string(routeapi.TLSTerminationReencrypt),
+		}))
+	}
+
+	if tls.InsecureEdgeTerminationPolicy == "Redirect" && tls.Termination == routeapi.TLSTerminationPassthrough {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("insecureEdgeTerminationPolicy"), tls.InsecureEdgeTerminationPolicy, "the Redirect policy is only valid for edge and reencrypt termination"))
+	}
+
+	return allErrs
+}
+
+// verifyCertificate checks that certPEM parses, chains up to caPEM when
+// provided, and covers host via its Subject Alternative Names. certPEM may
+// contain the leaf certificate followed by intermediate CA certificates, as
+// is common in a "full chain" PEM bundle.
+func verifyCertificate(certPEM, caPEM, host string) error {
+	leaf, intermediates, err := parseCertificateChain(certPEM)
+	if err != nil {
+		return err
+	}
+
+	opts := x509.VerifyOptions{Intermediates: intermediates}
+	if caPEM != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(caPEM)) {
+			return errInvalidCACertificate
+		}
+		opts.Roots = pool
+		if _, err := leaf.Verify(opts); err != nil {
+			return err
+		}
+	}
+
+	if host != "" {
+		return leaf.VerifyHostname(host)
+	}
+	return nil
+}
+
+// parseCertificateChain parses the leaf certificate and any following
+// intermediate certificates out of a PEM bundle.
+func parseCertificateChain(certPEM string) (*x509.Certificate, *x509.CertPool, error) {
+	rest := []byte(certPEM)
+	var leaf *x509.Certificate
+	intermediates := x509.NewCertPool()
+
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		if leaf == nil {
+			leaf = cert
+		} else {
+			intermediates.AddCert(cert)
+		}
+	}
+
+	if leaf == nil {
+		return nil, nil, errInvalidPEM
+	}
+	return leaf, intermediates, nil
+}
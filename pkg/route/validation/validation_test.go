@@ -0,0 +1,227 @@
+package validation
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	routeapi "github.com/openshift/origin/pkg/route/api/v1beta3"
+)
+
+// selfSignedCert generates a self-signed certificate for host, optionally
+// signed by parent/parentKey to build a leaf+intermediate chain, and returns
+// its PEM encoding alongside the certificate and key for further chaining.
+func selfSignedCert(t *testing.T, host string, isCA bool, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (string, *x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: host},
+		DNSNames:              []string{host},
+		NotBefore:             time.Unix(0, 0),
+		NotAfter:              time.Unix(0, 0).Add(100 * 365 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+	}
+
+	signer := template
+	signerKey := key
+	if parent != nil {
+		signer = parent
+		signerKey = parentKey
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse generated certificate: %v", err)
+	}
+
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	return string(pemBytes), cert, key
+}
+
+func TestVerifyCertificateHostnameMismatch(t *testing.T) {
+	certPEM, _, _ := selfSignedCert(t, "www.example.com", false, nil, nil)
+
+	if err := verifyCertificate(certPEM, "", "other.example.com"); err == nil {
+		t.Fatal("expected an error for a certificate that does not cover the requested host")
+	}
+}
+
+func TestVerifyCertificateHostnameMatch(t *testing.T) {
+	certPEM, _, _ := selfSignedCert(t, "www.example.com", false, nil, nil)
+
+	if err := verifyCertificate(certPEM, "", "www.example.com"); err != nil {
+		t.Fatalf("unexpected error for a matching hostname: %v", err)
+	}
+}
+
+func TestVerifyCertificateChainWithIntermediate(t *testing.T) {
+	caPEM, caCert, caKey := selfSignedCert(t, "ca.example.com", true, nil, nil)
+	leafPEM, leafCert, _ := selfSignedCert(t, "www.example.com", false, caCert, caKey)
+
+	// A "full chain" PEM bundle carries the leaf followed by its intermediates.
+	bundle := leafPEM + caPEM
+
+	if err := verifyCertificate(bundle, caPEM, "www.example.com"); err != nil {
+		t.Fatalf("unexpected error verifying a leaf chained to its CA: %v", err)
+	}
+
+	leaf, intermediates, err := parseCertificateChain(bundle)
+	if err != nil {
+		t.Fatalf("unexpected error parsing chain: %v", err)
+	}
+	if leaf.Subject.CommonName != leafCert.Subject.CommonName {
+		t.Errorf("parsed leaf CommonName = %q, want %q", leaf.Subject.CommonName, leafCert.Subject.CommonName)
+	}
+	if intermediates.Subjects() == nil || len(intermediates.Subjects()) != 1 {
+		t.Errorf("expected exactly one intermediate in the pool")
+	}
+}
+
+func TestVerifyCertificateUntrustedCA(t *testing.T) {
+	_, caCert, caKey := selfSignedCert(t, "ca.example.com", true, nil, nil)
+	leafPEM, _, _ := selfSignedCert(t, "www.example.com", false, caCert, caKey)
+
+	otherCAPEM, _, _ := selfSignedCert(t, "other-ca.example.com", true, nil, nil)
+
+	if err := verifyCertificate(leafPEM, otherCAPEM, "www.example.com"); err == nil {
+		t.Fatal("expected an error when the supplied CA does not sign the leaf")
+	}
+}
+
+func TestParseCertificateChainInvalidPEM(t *testing.T) {
+	if _, _, err := parseCertificateChain("not a certificate"); err != errInvalidPEM {
+		t.Errorf("got error %v, want errInvalidPEM", err)
+	}
+}
+
+func TestValidateRouteCertificateKeyPairing(t *testing.T) {
+	certPEM, _, _ := selfSignedCert(t, "www.example.com", false, nil, nil)
+
+	route := &routeapi.Route{
+		Spec: routeapi.RouteSpec{
+			Host: "www.example.com",
+			TLS: &routeapi.TLSConfig{
+				Termination: routeapi.TLSTerminationEdge,
+				Certificate: certPEM,
+			},
+		},
+	}
+
+	errs := ValidateRoute(route)
+	if len(errs) != 1 {
+		t.Fatalf("expected exactly one error for a certificate without a matching key, got %v", errs)
+	}
+}
+
+func TestValidateRoutePassthroughRejectsCertificate(t *testing.T) {
+	certPEM, _, _ := selfSignedCert(t, "www.example.com", false, nil, nil)
+
+	route := &routeapi.Route{
+		Spec: routeapi.RouteSpec{
+			Host: "www.example.com",
+			TLS: &routeapi.TLSConfig{
+				Termination: routeapi.TLSTerminationPassthrough,
+				Certificate: certPEM,
+			},
+		},
+	}
+
+	if errs := ValidateRoute(route); len(errs) != 1 {
+		t.Fatalf("expected exactly one error for a passthrough route carrying a certificate, got %v", errs)
+	}
+}
+
+func TestValidateTargetWeightBounds(t *testing.T) {
+	tooLow := int32(0)
+	tooHigh := int32(257)
+	ok := int32(100)
+
+	cases := []struct {
+		name    string
+		weight  *int32
+		wantErr bool
+	}{
+		{"nil weight left to defaulting", nil, false},
+		{"minimum valid weight", &[]int32{1}[0], false},
+		{"maximum valid weight", &[]int32{256}[0], false},
+		{"in range", &ok, false},
+		{"below range", &tooLow, true},
+		{"above range", &tooHigh, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			route := &routeapi.Route{
+				Spec: routeapi.RouteSpec{
+					To: routeapi.RouteTargetReference{Kind: "Service", Name: "svc", Weight: c.weight},
+				},
+			}
+			errs := ValidateRoute(route)
+			if c.wantErr && len(errs) == 0 {
+				t.Error("expected a validation error, got none")
+			}
+			if !c.wantErr && len(errs) != 0 {
+				t.Errorf("expected no validation error, got %v", errs)
+			}
+		})
+	}
+}
+
+func TestValidateTargetKind(t *testing.T) {
+	cases := []struct {
+		name    string
+		kind    string
+		wantErr bool
+	}{
+		{"empty kind left to defaulting", "", false},
+		{"Service is allowed", "Service", false},
+		{"Pod is rejected", "Pod", true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			route := &routeapi.Route{
+				Spec: routeapi.RouteSpec{
+					To: routeapi.RouteTargetReference{Kind: c.kind, Name: "svc"},
+				},
+			}
+			errs := ValidateRoute(route)
+			if c.wantErr && len(errs) == 0 {
+				t.Error("expected a validation error, got none")
+			}
+			if !c.wantErr && len(errs) != 0 {
+				t.Errorf("expected no validation error, got %v", errs)
+			}
+		})
+	}
+}
+
+func TestValidateAlternateBackendKindRejected(t *testing.T) {
+	route := &routeapi.Route{
+		Spec: routeapi.RouteSpec{
+			To:                routeapi.RouteTargetReference{Kind: "Service", Name: "primary"},
+			AlternateBackends: []routeapi.RouteTargetReference{{Kind: "Pod", Name: "alt"}},
+		},
+	}
+	if errs := ValidateRoute(route); len(errs) == 0 {
+		t.Error("expected a validation error for an AlternateBackends entry with an unsupported Kind")
+	}
+}
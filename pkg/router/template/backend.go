@@ -0,0 +1,139 @@
+package template
+
+import (
+	"fmt"
+
+	kapi "k8s.io/kubernetes/pkg/api/v1beta3"
+
+	routeapi "github.com/openshift/origin/pkg/route/api/v1beta3"
+)
+
+// WeightedServer is a single HAProxy "server" line contributed by one
+// endpoint address of a weighted backend service.
+type WeightedServer struct {
+	// Name uniquely identifies the server line within its backend.
+	Name string
+	// Address is host:port of the endpoint this server represents.
+	Address string
+	// Weight is the HAProxy server weight, already normalized into
+	// [1,256].
+	Weight int32
+}
+
+// WeightedBackend is the compiled HAProxy backend stanza for a Route's 'to'
+// plus AlternateBackends, grouping every target service's endpoints under a
+// single backend name.
+type WeightedBackend struct {
+	// Name is the deterministic HAProxy backend name for this route.
+	Name    string
+	Servers []WeightedServer
+}
+
+// BackendName derives a deterministic HAProxy backend name for a route so
+// that re-compiling the same route always yields the same name, independent
+// of map iteration order elsewhere in the template pipeline.
+func BackendName(namespace, route string) string {
+	return fmt.Sprintf("be_%s_%s", namespace, route)
+}
+
+// CompileWeightedBackend builds the weighted HAProxy backend for route,
+// looking up each target's endpoints in endpointsByService (keyed by
+// service name) and normalizing weights per mode.
+func CompileWeightedBackend(route *routeapi.Route, endpointsByService map[string]*kapi.Endpoints, mode WeightNormalizationType) (*WeightedBackend, error) {
+	targets := append([]routeapi.RouteTargetReference{route.Spec.To}, route.Spec.AlternateBackends...)
+
+	weights := make([]int32, len(targets))
+	for i, t := range targets {
+		if t.Weight != nil {
+			weights[i] = *t.Weight
+		} else {
+			weights[i] = 100
+		}
+	}
+
+	normalized, err := normalizeWeights(weights, mode)
+	if err != nil {
+		return nil, fmt.Errorf("route %s/%s: %v", route.Namespace, route.Name, err)
+	}
+
+	// Count each target's eligible endpoint addresses up front so the
+	// target's declared weight can be divided evenly across them: HAProxy
+	// sums the per-server weights of a backend, so handing every address
+	// the target's full weight would make the backend's aggregate weight
+	// scale with replica count instead of tracking the declared Weight.
+	endpointCounts := make([]int, len(targets))
+	for i, t := range targets {
+		endpoints := endpointsByService[t.Name]
+		if endpoints == nil {
+			continue
+		}
+		for _, subset := range endpoints.Subsets {
+			if targetPort(route, subset) == 0 {
+				continue
+			}
+			endpointCounts[i] += len(subset.Addresses)
+		}
+	}
+
+	backend := &WeightedBackend{Name: BackendName(route.Namespace, route.Name)}
+	for i, t := range targets {
+		endpoints := endpointsByService[t.Name]
+		if endpoints == nil {
+			continue
+		}
+		perAddrWeight := int32(1)
+		if endpointCounts[i] > 0 {
+			perAddrWeight = normalized[i] / int32(endpointCounts[i])
+			if perAddrWeight < 1 {
+				perAddrWeight = 1
+			}
+		}
+		for _, subset := range endpoints.Subsets {
+			port := targetPort(route, subset)
+			if port == 0 {
+				continue
+			}
+			for _, addr := range subset.Addresses {
+				backend.Servers = append(backend.Servers, WeightedServer{
+					Name:    fmt.Sprintf("%s:%s", t.Name, addr.IP),
+					Address: fmt.Sprintf("%s:%d", addr.IP, port),
+					Weight:  perAddrWeight,
+				})
+			}
+		}
+	}
+
+	return backend, nil
+}
+
+// targetPort resolves the port a backend's endpoints should be contacted on.
+// When route.Spec.Port names a target port, the subset port of that name is
+// used, or 0 if the subset has none. In every other case, including a
+// numeric target port, the subset's first port is used; a numeric target
+// port is not matched against the subset's port numbers.
+func targetPort(route *routeapi.Route, subset kapi.EndpointSubset) int32 {
+	if route.Spec.Port != nil {
+		name := route.Spec.Port.TargetPort.StrVal
+		for _, p := range subset.Ports {
+			if name == "" || p.Name == name {
+				return p.Port
+			}
+		}
+		return 0
+	}
+	if len(subset.Ports) == 0 {
+		return 0
+	}
+	return subset.Ports[0].Port
+}
+
+// Render writes backend's HAProxy configuration stanza. It is intentionally
+// minimal: the surrounding template is responsible for the "backend <name>"
+// header and any global backend options.
+func (b *WeightedBackend) Render() string {
+	out := ""
+	for _, s := range b.Servers {
+		out += fmt.Sprintf("  server %s %s weight %d\n", s.Name, s.Address, s.Weight)
+	}
+	return out
+}
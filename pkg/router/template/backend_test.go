@@ -0,0 +1,90 @@
+package template
+
+import (
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api/v1beta3"
+
+	routeapi "github.com/openshift/origin/pkg/route/api/v1beta3"
+)
+
+func endpointsWithAddrs(n int, port int32) *kapi.Endpoints {
+	addrs := make([]kapi.EndpointAddress, n)
+	for i := range addrs {
+		addrs[i] = kapi.EndpointAddress{IP: "10.0.0.1"}
+	}
+	return &kapi.Endpoints{
+		Subsets: []kapi.EndpointSubset{
+			{
+				Addresses: addrs,
+				Ports:     []kapi.EndpointPort{{Port: port}},
+			},
+		},
+	}
+}
+
+func weightRef(name string, weight int32) routeapi.RouteTargetReference {
+	w := weight
+	return routeapi.RouteTargetReference{Kind: "Service", Name: name, Weight: &w}
+}
+
+// TestCompileWeightedBackendDividesWeightAcrossEndpoints verifies that a
+// target's declared weight is spread across its endpoint addresses, so that
+// the backend's HAProxy-aggregate weight tracks the declared Weight rather
+// than the target's replica count.
+func TestCompileWeightedBackendDividesWeightAcrossEndpoints(t *testing.T) {
+	route := &routeapi.Route{
+		ObjectMeta: kapi.ObjectMeta{Namespace: "ns", Name: "r"},
+		Spec: routeapi.RouteSpec{
+			To: weightRef("ten-pods", 100),
+			AlternateBackends: []routeapi.RouteTargetReference{
+				weightRef("one-pod", 100),
+			},
+		},
+	}
+	endpoints := map[string]*kapi.Endpoints{
+		"ten-pods": endpointsWithAddrs(10, 8080),
+		"one-pod":  endpointsWithAddrs(1, 8080),
+	}
+
+	backend, err := CompileWeightedBackend(route, endpoints, WeightNormalizationRaw)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var tenPodsTotal, onePodTotal int32
+	for _, s := range backend.Servers {
+		switch {
+		case len(s.Name) >= len("ten-pods") && s.Name[:len("ten-pods")] == "ten-pods":
+			tenPodsTotal += s.Weight
+		case len(s.Name) >= len("one-pod") && s.Name[:len("one-pod")] == "one-pod":
+			onePodTotal += s.Weight
+		}
+	}
+
+	if tenPodsTotal != onePodTotal {
+		t.Errorf("expected both targets to contribute equal aggregate weight for equal declared Weight, got ten-pods=%d one-pod=%d", tenPodsTotal, onePodTotal)
+	}
+}
+
+// TestCompileWeightedBackendMinimumWeightFloor verifies that dividing a small
+// weight across many endpoints never produces a zero HAProxy server weight.
+func TestCompileWeightedBackendMinimumWeightFloor(t *testing.T) {
+	route := &routeapi.Route{
+		ObjectMeta: kapi.ObjectMeta{Namespace: "ns", Name: "r"},
+		Spec:       routeapi.RouteSpec{To: weightRef("many-pods", 1)},
+	}
+	endpoints := map[string]*kapi.Endpoints{
+		"many-pods": endpointsWithAddrs(50, 8080),
+	}
+
+	backend, err := CompileWeightedBackend(route, endpoints, WeightNormalizationRaw)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, s := range backend.Servers {
+		if s.Weight < 1 {
+			t.Fatalf("server %s has weight %d, want >= 1", s.Name, s.Weight)
+		}
+	}
+}
@@ -0,0 +1,4 @@
+// Package template compiles Route objects into the backend stanzas consumed
+// by the HAProxy template router, including weighted load balancing across
+// a route's AlternateBackends.
+package template
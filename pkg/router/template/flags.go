@@ -0,0 +1,57 @@
+package template
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+const flagWeightNormalization = "weight-normalization"
+
+// PluginConfig holds the template router flags that affect how weighted
+// backends are compiled.
+type PluginConfig struct {
+	// WeightNormalization selects how AlternateBackends weights are mapped
+	// onto HAProxy's 1-256 server weight range.
+	WeightNormalization WeightNormalizationType
+}
+
+// NewPluginConfig creates a PluginConfig with the router's default weight
+// normalization behavior.
+func NewPluginConfig() *PluginConfig {
+	return &PluginConfig{WeightNormalization: WeightNormalizationScale}
+}
+
+// AddFlags registers the weight-normalization flag on cmd.
+func (c *PluginConfig) AddFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.Var(&weightNormalizationValue{&c.WeightNormalization}, flagWeightNormalization,
+		"How to map route AlternateBackends weights onto HAProxy's 1-256 server weight range: scale or raw")
+}
+
+// weightNormalizationValue adapts WeightNormalizationType to pflag.Value so
+// it can be set and validated directly from the command line.
+type weightNormalizationValue struct {
+	mode *WeightNormalizationType
+}
+
+func (v *weightNormalizationValue) String() string {
+	if *v.mode == "" {
+		return string(WeightNormalizationScale)
+	}
+	return string(*v.mode)
+}
+
+func (v *weightNormalizationValue) Set(s string) error {
+	switch WeightNormalizationType(s) {
+	case WeightNormalizationScale, WeightNormalizationRaw:
+		*v.mode = WeightNormalizationType(s)
+		return nil
+	default:
+		return fmt.Errorf("invalid value %q for --%s: must be 'scale' or 'raw'", s, flagWeightNormalization)
+	}
+}
+
+func (v *weightNormalizationValue) Type() string {
+	return "string"
+}
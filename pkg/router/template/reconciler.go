@@ -0,0 +1,86 @@
+package template
+
+import (
+	"fmt"
+
+	unversioned "k8s.io/kubernetes/pkg/api/unversioned"
+	kapi "k8s.io/kubernetes/pkg/api/v1beta3"
+
+	routeapi "github.com/openshift/origin/pkg/route/api/v1beta3"
+)
+
+// WeightedBackendReconciler recomputes a route's compiled WeightedBackend
+// against a given snapshot of Endpoints and records the outcome onto the
+// route's in-memory status as a RouteIngressCondition. It does not watch
+// Endpoints or persist the route itself: the caller is expected to trigger
+// ReconcileEndpoints when a targeted service's Endpoints change and to
+// write the mutated route's status back to the API.
+type WeightedBackendReconciler struct {
+	shardName  string
+	routerName string
+	mode       WeightNormalizationType
+}
+
+// NewWeightedBackendReconciler creates a reconciler that reports admission
+// results under routerName for the given shard.
+func NewWeightedBackendReconciler(shardName, routerName string, mode WeightNormalizationType) *WeightedBackendReconciler {
+	return &WeightedBackendReconciler{
+		shardName:  shardName,
+		routerName: routerName,
+		mode:       mode,
+	}
+}
+
+// ReconcileEndpoints recomputes the weighted backend for route against the
+// current endpoints of every service it targets, then updates route.Status
+// in place with a RouteIngressCondition recording which backends were
+// admitted for this shard's router. It does not persist route; the caller
+// owns writing the status update back to the API, retrying on conflict.
+func (r *WeightedBackendReconciler) ReconcileEndpoints(route *routeapi.Route, endpointsByService map[string]*kapi.Endpoints) (*WeightedBackend, error) {
+	backend, err := CompileWeightedBackend(route, endpointsByService, r.mode)
+	if err != nil {
+		r.recordCondition(route, kapi.ConditionFalse, "WeightCompileFailed", err.Error())
+		return nil, err
+	}
+
+	admitted := fmt.Sprintf("%s (%d servers)", backend.Name, len(backend.Servers))
+	r.recordCondition(route, kapi.ConditionTrue, "BackendAdmitted", admitted)
+	return backend, nil
+}
+
+// recordCondition updates the RouteAdmitted condition of the RouteIngress
+// entry for r.routerName on route. Callers own persisting the route and
+// retrying on conflict, mirroring how other router status writers behave.
+func (r *WeightedBackendReconciler) recordCondition(route *routeapi.Route, status kapi.ConditionStatus, reason, message string) {
+	now := unversioned.Now()
+	condition := routeapi.RouteIngressCondition{
+		Type:               routeapi.RouteAdmitted,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: &now,
+	}
+
+	for i, ingress := range route.Status.Ingress {
+		if ingress.RouterName == r.routerName {
+			route.Status.Ingress[i].Conditions = replaceCondition(ingress.Conditions, condition)
+			return
+		}
+	}
+
+	route.Status.Ingress = append(route.Status.Ingress, routeapi.RouteIngress{
+		Host:       route.Spec.Host,
+		RouterName: r.routerName,
+		Conditions: []routeapi.RouteIngressCondition{condition},
+	})
+}
+
+func replaceCondition(conditions []routeapi.RouteIngressCondition, condition routeapi.RouteIngressCondition) []routeapi.RouteIngressCondition {
+	for i, c := range conditions {
+		if c.Type == condition.Type {
+			conditions[i] = condition
+			return conditions
+		}
+	}
+	return append(conditions, condition)
+}
@@ -0,0 +1,65 @@
+package template
+
+import "fmt"
+
+// WeightNormalizationType controls how a route's AlternateBackends weights
+// are mapped onto HAProxy's 1-256 per-server weight range.
+type WeightNormalizationType string
+
+const (
+	// WeightNormalizationScale rescales weights proportionally so their sum
+	// fits within maxBackendWeight when it would otherwise overflow.
+	WeightNormalizationScale WeightNormalizationType = "scale"
+	// WeightNormalizationRaw passes weights through unchanged and rejects
+	// any backend whose weight falls outside the valid HAProxy range.
+	WeightNormalizationRaw WeightNormalizationType = "raw"
+)
+
+const (
+	minBackendWeight = 1
+	maxBackendWeight = 256
+)
+
+// normalizeWeights validates and, for WeightNormalizationScale, rescales
+// weights so they sit within [minBackendWeight, maxBackendWeight]. weights is
+// never modified: it is returned as is when no rescaling is needed, and a
+// newly allocated slice is returned when it is rescaled.
+func normalizeWeights(weights []int32, mode WeightNormalizationType) ([]int32, error) {
+	sum := int32(0)
+	for _, w := range weights {
+		sum += w
+	}
+
+	switch mode {
+	case WeightNormalizationRaw:
+		for _, w := range weights {
+			if w < minBackendWeight || w > maxBackendWeight {
+				return nil, fmt.Errorf("weight %d is outside the valid HAProxy range [%d,%d]", w, minBackendWeight, maxBackendWeight)
+			}
+		}
+		return weights, nil
+
+	case WeightNormalizationScale, "":
+		if sum <= maxBackendWeight {
+			return weights, nil
+		}
+		scaled := make([]int32, len(weights))
+		for i, w := range weights {
+			scaled[i] = scaleWeight(w, sum)
+		}
+		return scaled, nil
+
+	default:
+		return nil, fmt.Errorf("unrecognized weight normalization mode %q", mode)
+	}
+}
+
+// scaleWeight rescales w so that a total of sum maps onto maxBackendWeight,
+// clamped to at least minBackendWeight so no backend is starved to zero.
+func scaleWeight(w, sum int32) int32 {
+	scaled := int32((int64(w) * int64(maxBackendWeight)) / int64(sum))
+	if scaled < minBackendWeight {
+		return minBackendWeight
+	}
+	return scaled
+}
@@ -0,0 +1,49 @@
+package template
+
+import "testing"
+
+func TestNormalizeWeightsRaw(t *testing.T) {
+	if _, err := normalizeWeights([]int32{1, 256}, WeightNormalizationRaw); err != nil {
+		t.Errorf("unexpected error for in-range weights: %v", err)
+	}
+	if _, err := normalizeWeights([]int32{0}, WeightNormalizationRaw); err == nil {
+		t.Error("expected an error for a weight below the valid range")
+	}
+	if _, err := normalizeWeights([]int32{257}, WeightNormalizationRaw); err == nil {
+		t.Error("expected an error for a weight above the valid range")
+	}
+}
+
+func TestNormalizeWeightsScaleWithinRange(t *testing.T) {
+	weights := []int32{10, 20}
+	got, err := normalizeWeights(weights, WeightNormalizationScale)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got[0] != 10 || got[1] != 20 {
+		t.Errorf("expected weights already within range to pass through unchanged, got %v", got)
+	}
+}
+
+func TestNormalizeWeightsScaleOverflow(t *testing.T) {
+	// Sum exceeds maxBackendWeight, so weights must be rescaled proportionally.
+	weights := []int32{100, 300}
+	got, err := normalizeWeights(weights, WeightNormalizationScale)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, w := range got {
+		if w < minBackendWeight || w > maxBackendWeight {
+			t.Errorf("scaled weight %d outside valid range [%d,%d]", w, minBackendWeight, maxBackendWeight)
+		}
+	}
+	if got[1] <= got[0] {
+		t.Errorf("expected the larger declared weight to remain larger after scaling, got %v", got)
+	}
+}
+
+func TestNormalizeWeightsUnrecognizedMode(t *testing.T) {
+	if _, err := normalizeWeights([]int32{100}, "bogus"); err == nil {
+		t.Error("expected an error for an unrecognized normalization mode")
+	}
+}